@@ -0,0 +1,108 @@
+package glexa
+
+import (
+	"container/list"
+	"crypto/x509"
+	"sync"
+	"time"
+)
+
+// defaultCertCacheSize is the number of certificate chains an lruCertCache
+// keeps before evicting the least recently used entry.
+const defaultCertCacheSize = 64
+
+// defaultCertCacheTTL bounds how long a cached certificate is trusted even
+// if its NotAfter is further out, so a revoked-but-not-yet-expired chain
+// doesn't stick around forever.
+const defaultCertCacheTTL = time.Hour
+
+// CertCache stores certificates parsed from a SignatureCertChainUrl so
+// Verifier does not have to re-fetch and re-validate them on every
+// request.
+type CertCache interface {
+	// Get returns the cached certificate for url, if present and not
+	// expired.
+	Get(url string) (*x509.Certificate, bool)
+
+	// Put caches cert for url until expiresAt.
+	Put(url string, cert *x509.Certificate, expiresAt time.Time)
+}
+
+type certCacheEntry struct {
+	url       string
+	cert      *x509.Certificate
+	expiresAt time.Time
+}
+
+// lruCertCache is an in-memory, size-bounded CertCache.
+type lruCertCache struct {
+	mu       sync.Mutex
+	size     int
+	ttl      time.Duration
+	ll       *list.List
+	elements map[string]*list.Element
+}
+
+func newLRUCertCache(size int, ttl time.Duration) *lruCertCache {
+	if size <= 0 {
+		size = defaultCertCacheSize
+	}
+	if ttl <= 0 {
+		ttl = defaultCertCacheTTL
+	}
+	return &lruCertCache{
+		size:     size,
+		ttl:      ttl,
+		ll:       list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCertCache) Get(url string) (*x509.Certificate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.elements[url]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*certCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.ll.Remove(el)
+		delete(c.elements, url)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return entry.cert, true
+}
+
+func (c *lruCertCache) Put(url string, cert *x509.Certificate, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// never trust a cache entry longer than c.ttl, even if the certificate
+	// itself is valid for longer.
+	if ttlExpiry := time.Now().Add(c.ttl); ttlExpiry.Before(expiresAt) {
+		expiresAt = ttlExpiry
+	}
+
+	if el, ok := c.elements[url]; ok {
+		el.Value.(*certCacheEntry).cert = cert
+		el.Value.(*certCacheEntry).expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	el := c.ll.PushFront(&certCacheEntry{url: url, cert: cert, expiresAt: expiresAt})
+	c.elements[url] = el
+
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.elements, oldest.Value.(*certCacheEntry).url)
+		}
+	}
+}
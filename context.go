@@ -0,0 +1,64 @@
+package glexa
+
+import "net/http"
+
+// Context carries everything a Skill handler needs for a single Alexa
+// request: the parsed request body, the response being built, and the
+// raw HTTP request that delivered it.
+type Context struct {
+	// Body is the parsed incoming Alexa request.
+	Body *Body
+
+	// Response is returned to Alexa once the handler returns.
+	Response *Response
+
+	// Request is the raw HTTP request that carried Body.
+	Request *http.Request
+}
+
+func newContext(body *Body, r *http.Request) *Context {
+	return &Context{
+		Body:     body,
+		Response: NewResponse(),
+		Request:  r,
+	}
+}
+
+// Slot returns the value of the named intent slot, or "" if the slot was
+// not supplied.
+func (c *Context) Slot(name string) string {
+	slot, ok := c.Body.Request.Intent.Slots[name]
+	if !ok {
+		return ""
+	}
+	return slot.Value
+}
+
+// SlotResolvedID returns the canonical entity ID for the named slot from
+// its first ER_SUCCESS_MATCH resolution, or "" if the slot was not
+// resolved against an entity resolution authority.
+func (c *Context) SlotResolvedID(name string) string {
+	slot, ok := c.Body.Request.Intent.Slots[name]
+	if !ok {
+		return ""
+	}
+	for _, res := range slot.Resolutions.ResolutionsPerAuthority {
+		if res.Status.Code != "ER_SUCCESS_MATCH" || len(res.Values) == 0 {
+			continue
+		}
+		return res.Values[0].Value.ID
+	}
+	return ""
+}
+
+// SessionAttribute returns the named session attribute sent with the
+// incoming request.
+func (c *Context) SessionAttribute(key string) (interface{}, bool) {
+	val, ok := c.Body.Session.Attributes[key]
+	return val, ok
+}
+
+// SetSessionAttribute sets a session attribute on the outgoing response.
+func (c *Context) SetSessionAttribute(key string, value interface{}) {
+	c.Response.SessionAttribute(key, value)
+}
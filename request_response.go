@@ -14,32 +14,67 @@ type Body struct {
 		Application struct {
 			ApplicationID string `json:"applicationId"`
 		} `json:"application"`
-		Attributes map[string]struct {
-			Key   string `json:"key"`
-			Value string `json:"value"`
-		} `json: "attributes"`
+		Attributes map[string]interface{} `json:"attributes"`
 		User struct {
 			UserID      string `json:"userId"`
 			AccessToken string `json:"accessToken"`
 		} `json:"user,omitempty"`
 	} `json:"session"`
+	// Context carries request metadata that is always present, even on
+	// sessionless requests (e.g. AudioPlayer.* directives) where Session is
+	// empty.
+	Context struct {
+		System struct {
+			Application struct {
+				ApplicationID string `json:"applicationId"`
+			} `json:"application"`
+		} `json:"System"`
+	} `json:"context"`
 	Request alexaRequest `json:"request"`
 }
 
+// HasSession reports whether the request carries an active session, as
+// opposed to a sessionless request such as an AudioPlayer.* directive.
+func (b *Body) HasSession() bool {
+	return b.Session.SessionID != ""
+}
+
 type alexaRequest struct {
-	Type      string `json:"type"`
-	RequestID string `json:"requestId"`
-	Timestamp string `json:"timestamp"`
-	Reason    string `json:"reason,omitempty"`
-	Intent    struct {
+	Type        string `json:"type"`
+	RequestID   string `json:"requestId"`
+	Timestamp   string `json:"timestamp"`
+	Reason      string `json:"reason,omitempty"`
+	DialogState string `json:"dialogState,omitempty"`
+	Intent      struct {
 		Name  string `json:"name"`
 		Slots map[string]struct {
-			Key   string `json:"key"`
-			Value string `json:"value"`
+			Key                string               `json:"key"`
+			Value              string               `json:"value"`
+			ConfirmationStatus string               `json:"confirmationStatus,omitempty"`
+			Resolutions        alexaSlotResolutions `json:"resolutions,omitempty"`
 		} `json:"slots"`
 	} `json:"intent,omitempty"`
 }
 
+// alexaSlotResolutions carries the entity-resolution matches Alexa found
+// for a slot value against each configured resolutions authority.
+type alexaSlotResolutions struct {
+	ResolutionsPerAuthority []alexaSlotResolution `json:"resolutionsPerAuthority"`
+}
+
+type alexaSlotResolution struct {
+	Authority string `json:"authority"`
+	Status    struct {
+		Code string `json:"code"`
+	} `json:"status"`
+	Values []struct {
+		Value struct {
+			Name string `json:"name"`
+			ID   string `json:"id"`
+		} `json:"value"`
+	} `json:"values"`
+}
+
 type alexaSpeech struct {
 	Type string `json:"type"`
 	Text string `json:"text,omitempty"`
@@ -64,20 +99,38 @@ type alexaReprompt struct {
 }
 
 type alexaResponse struct {
-	OutputSpeech     *alexaSpeech   `json:"outputSpeech,omitempty"`
-	Card             *alexaCard     `json:"card,omitempty"`
-	Reprompt         *alexaReprompt `json:"reprompt,omitempty"`
-	ShouldEndSession bool           `json:"shouldEndSession"`
+	OutputSpeech     *alexaSpeech     `json:"outputSpeech,omitempty"`
+	Card             *alexaCard       `json:"card,omitempty"`
+	Reprompt         *alexaReprompt   `json:"reprompt,omitempty"`
+	Directives       []alexaDirective `json:"directives,omitempty"`
+	ShouldEndSession bool             `json:"shouldEndSession"`
+}
+
+// alexaDirective is emitted in response.directives to ask a device to
+// perform an out-of-band action, such as playing audio.
+type alexaDirective struct {
+	Type          string          `json:"type"`
+	PlayBehavior  string          `json:"playBehavior,omitempty"`
+	AudioItem     *alexaAudioItem `json:"audioItem,omitempty"`
+	SlotToElicit  string          `json:"slotToElicit,omitempty"`
+	SlotToConfirm string          `json:"slotToConfirm,omitempty"`
+}
+
+type alexaAudioItem struct {
+	Stream alexaAudioStream `json:"stream"`
+}
+
+type alexaAudioStream struct {
+	URL                  string `json:"url"`
+	Token                string `json:"token"`
+	OffsetInMilliseconds int    `json:"offsetInMilliseconds"`
 }
 
 // Response is the response object for an Alexa Request
 type Response struct {
-	Version           string `json:"version"`
-	SessionAttributes map[string]struct {
-		Key   string `json:"key"`
-		Value string `json:"value"`
-	} `json:"sessionAttributes,omitempty"`
-	Response alexaResponse `json:"response,omitempty"`
+	Version           string                 `json:"version"`
+	SessionAttributes map[string]interface{} `json:"sessionAttributes,omitempty"`
+	Response          alexaResponse          `json:"response,omitempty"`
 }
 
 // ParseBody returns a new Body struct
@@ -128,3 +181,131 @@ func (r *Response) Ask(text string) {
 	}
 	r.Response.ShouldEndSession = false
 }
+
+// TellSSML responds with SSML-formatted speech
+func (r *Response) TellSSML(ssml string) {
+	r.Response.OutputSpeech = &alexaSpeech{
+		Type: "SSML",
+		SSML: ssml,
+	}
+}
+
+// AskSSML responds with SSML-formatted speech and an SSML reprompt to
+// prompt retry
+func (r *Response) AskSSML(ssml, reprompt string) {
+	r.Response.OutputSpeech = &alexaSpeech{
+		Type: "SSML",
+		SSML: ssml,
+	}
+	r.Response.Reprompt = &alexaReprompt{
+		OutputSpeech: &alexaSpeech{
+			Type: "SSML",
+			SSML: reprompt,
+		},
+	}
+	r.Response.ShouldEndSession = false
+}
+
+// WithSimpleCard attaches a simple, title + plain text card
+func (r *Response) WithSimpleCard(title, content string) {
+	r.Response.Card = &alexaCard{
+		Type:    "Simple",
+		Title:   title,
+		Content: content,
+	}
+}
+
+// WithStandardCard attaches a card with a title, body text, and an image
+func (r *Response) WithStandardCard(title, text, smallURL, largeURL string) {
+	r.Response.Card = &alexaCard{
+		Type:  "Standard",
+		Title: title,
+		Text:  text,
+		Image: &alexaCardImage{
+			SmallImageURL: smallURL,
+			LargeImageURL: largeURL,
+		},
+	}
+}
+
+// WithLinkAccountCard attaches a card prompting the user to link their
+// account via the Alexa companion app
+func (r *Response) WithLinkAccountCard() {
+	r.Response.Card = &alexaCard{
+		Type: "LinkAccount",
+	}
+}
+
+// KeepSession leaves the session open so Alexa expects a follow-up request
+func (r *Response) KeepSession() {
+	r.Response.ShouldEndSession = false
+}
+
+// EndSession closes the session after this response is delivered
+func (r *Response) EndSession() {
+	r.Response.ShouldEndSession = true
+}
+
+// SessionAttribute sets a session attribute that will be round-tripped
+// back on the next request for this session
+func (r *Response) SessionAttribute(key string, value interface{}) {
+	if r.SessionAttributes == nil {
+		r.SessionAttributes = make(map[string]interface{})
+	}
+	r.SessionAttributes[key] = value
+}
+
+// AudioPlayerPlay emits an AudioPlayer.Play directive to stream audio from
+// url, resuming at offsetMs if non-zero
+func (r *Response) AudioPlayerPlay(url, token string, offsetMs int) {
+	r.Response.Directives = append(r.Response.Directives, alexaDirective{
+		Type:         "AudioPlayer.Play",
+		PlayBehavior: "REPLACE_ALL",
+		AudioItem: &alexaAudioItem{
+			Stream: alexaAudioStream{
+				URL:                  url,
+				Token:                token,
+				OffsetInMilliseconds: offsetMs,
+			},
+		},
+	})
+}
+
+// AudioPlayerStop emits an AudioPlayer.Stop directive
+func (r *Response) AudioPlayerStop() {
+	r.Response.Directives = append(r.Response.Directives, alexaDirective{
+		Type: "AudioPlayer.Stop",
+	})
+}
+
+// Delegate asks Alexa to handle the next turn of the dialog itself,
+// eliciting and confirming slots according to the interaction model
+func (r *Response) Delegate() {
+	r.Response.Directives = append(r.Response.Directives, alexaDirective{
+		Type: "Dialog.Delegate",
+	})
+}
+
+// ElicitSlot asks Alexa to prompt the user for the named slot
+func (r *Response) ElicitSlot(slotName string) {
+	r.Response.Directives = append(r.Response.Directives, alexaDirective{
+		Type:         "Dialog.ElicitSlot",
+		SlotToElicit: slotName,
+	})
+}
+
+// ConfirmSlot asks Alexa to confirm the value of the named slot with the
+// user
+func (r *Response) ConfirmSlot(slotName string) {
+	r.Response.Directives = append(r.Response.Directives, alexaDirective{
+		Type:          "Dialog.ConfirmSlot",
+		SlotToConfirm: slotName,
+	})
+}
+
+// ConfirmIntent asks Alexa to confirm the entire intent with the user
+func (r *Response) ConfirmIntent() {
+	r.Response.Directives = append(r.Response.Directives, alexaDirective{
+		Type: "Dialog.ConfirmIntent",
+	})
+}
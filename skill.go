@@ -0,0 +1,113 @@
+package glexa
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// HandlerFunc handles a single Alexa request dispatched by a Skill.
+type HandlerFunc func(ctx *Context)
+
+// Skill is an http.Handler that parses incoming Alexa requests and
+// dispatches them to handlers registered by request type or intent name,
+// saving users from hand-writing a switch over Body.Request.Type.
+type Skill struct {
+	// ExpectedApplicationID, if set, rejects any request whose
+	// Session.Application.ApplicationID does not match it, as required
+	// for skill certification. Sessionless requests are checked against
+	// Context.System.Application.ApplicationID instead.
+	ExpectedApplicationID string
+
+	// Verifier, if set, authenticates each request before it is
+	// dispatched. Leave nil to skip verification.
+	Verifier *Verifier
+
+	launch       HandlerFunc
+	sessionEnded HandlerFunc
+	fallback     HandlerFunc
+	intents      map[string]HandlerFunc
+}
+
+// NewSkill returns an empty Skill ready to have handlers registered.
+func NewSkill() *Skill {
+	return &Skill{
+		intents: make(map[string]HandlerFunc),
+	}
+}
+
+// OnLaunch registers the handler run for a LaunchRequest.
+func (s *Skill) OnLaunch(fn HandlerFunc) {
+	s.launch = fn
+}
+
+// OnIntent registers the handler run when an IntentRequest's intent name
+// matches name.
+func (s *Skill) OnIntent(name string, fn HandlerFunc) {
+	s.intents[name] = fn
+}
+
+// OnSessionEnded registers the handler run for a SessionEndedRequest.
+func (s *Skill) OnSessionEnded(fn HandlerFunc) {
+	s.sessionEnded = fn
+}
+
+// Fallback registers the handler run when no other registered handler
+// matches the incoming request.
+func (s *Skill) Fallback(fn HandlerFunc) {
+	s.fallback = fn
+}
+
+// ServeHTTP implements http.Handler. It runs request verification (if a
+// Verifier is configured), parses the body, dispatches to the matching
+// handler, and writes the resulting Response as JSON.
+func (s *Skill) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler := http.HandlerFunc(s.serve)
+	if s.Verifier != nil {
+		s.Verifier.Middleware(handler).ServeHTTP(w, r)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+func (s *Skill) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := ParseBody(r.Body)
+	if err != nil {
+		log.Printf("error: could not parse request body: %q\n", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	// Sessionless requests (e.g. AudioPlayer.* directives) carry no
+	// session, so fall back to context.System.application.applicationId,
+	// which is present on every request.
+	applicationID := body.Session.Application.ApplicationID
+	if !body.HasSession() {
+		applicationID = body.Context.System.Application.ApplicationID
+	}
+	if s.ExpectedApplicationID != "" && applicationID != s.ExpectedApplicationID {
+		log.Printf("error: unexpected application id: %q\n", applicationID)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	ctx := newContext(body, r)
+
+	switch {
+	case body.Request.IsLaunch() && s.launch != nil:
+		s.launch(ctx)
+	case body.Request.IsIntent() && s.intents[body.Request.Intent.Name] != nil:
+		s.intents[body.Request.Intent.Name](ctx)
+	case body.Request.IsSessionEnded() && s.sessionEnded != nil:
+		s.sessionEnded(ctx)
+	case s.fallback != nil:
+		s.fallback(ctx)
+	default:
+		log.Printf("warning: no handler registered for request type %q\n", body.Request.Type)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ctx.Response); err != nil {
+		log.Printf("error: could not encode response: %q\n", err)
+	}
+}
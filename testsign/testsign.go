@@ -0,0 +1,149 @@
+// Package testsign generates a throwaway, self-signed Alexa certificate
+// chain and signs request bodies against it, so glexa.Verifier can be
+// exercised against realistic signed traffic without contacting S3 or
+// depending on a live Amazon certificate.
+package testsign
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+)
+
+// CertPath is the URL path the fake chain is served at, mirroring the
+// "/echo.api/" prefix Amazon serves real certificates under.
+const CertPath = "/echo.api/cert.pem"
+
+// echoAPIHostname is the SAN the generated leaf certificate is issued
+// for, matching the hostname glexa.Verifier checks for.
+const echoAPIHostname = "echo-api.amazon.com"
+
+// Signer serves a self-signed certificate chain over HTTPS and signs
+// request bodies under its private key.
+type Signer struct {
+	// Server serves the PEM-encoded chain at CertPath.
+	Server *httptest.Server
+
+	// Cert is the generated, self-signed leaf certificate.
+	Cert *x509.Certificate
+
+	key      *rsa.PrivateKey
+	chainPEM []byte
+}
+
+// New generates an RSA key and a self-signed leaf certificate with
+// echo-api.amazon.com in its SAN, and starts serving the PEM chain.
+func New() (*Signer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate key: %q\n", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: echoAPIHostname},
+		DNSNames:              []string{echoAPIHostname},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create certificate: %q\n", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse certificate: %q\n", err)
+	}
+
+	s := &Signer{
+		Cert:     cert,
+		key:      key,
+		chainPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(CertPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(s.chainPEM)
+	})
+	s.Server = httptest.NewTLSServer(mux)
+	return s, nil
+}
+
+// Close shuts down the underlying httptest.Server.
+func (s *Signer) Close() {
+	s.Server.Close()
+}
+
+// ChainURL returns the fake SignatureCertChainUrl for the served chain.
+func (s *Signer) ChainURL() string {
+	return s.Server.URL + CertPath
+}
+
+// Host returns the hostname (without port) the chain is served from, for
+// use as Verifier.AllowedCertHost.
+func (s *Signer) Host() string {
+	u, _ := url.Parse(s.Server.URL)
+	return u.Hostname()
+}
+
+// Client returns an *http.Client that trusts the httptest.Server's TLS
+// certificate, for use as Verifier.HTTPClient.
+func (s *Signer) Client() *http.Client {
+	return s.Server.Client()
+}
+
+// RootPool returns a certificate pool trusting the generated, self-signed
+// leaf, for use as Verifier.Roots.
+func (s *Signer) RootPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(s.Cert)
+	return pool
+}
+
+// SignSHA1 signs body under SHA-1 and returns the base64-encoded
+// signature to send in the legacy Signature header.
+func (s *Signer) SignSHA1(body []byte) (string, error) {
+	return s.sign(body, crypto.SHA1)
+}
+
+// SignSHA256 signs body under SHA-256 and returns the base64-encoded
+// signature to send in the Signature-256 header.
+func (s *Signer) SignSHA256(body []byte) (string, error) {
+	return s.sign(body, crypto.SHA256)
+}
+
+func (s *Signer) sign(body []byte, hash crypto.Hash) (string, error) {
+	var hashed []byte
+	switch hash {
+	case crypto.SHA256:
+		sum := sha256.Sum256(body)
+		hashed = sum[:]
+	default:
+		sum := sha1.Sum(body)
+		hashed = sum[:]
+	}
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, hash, hashed)
+	if err != nil {
+		return "", fmt.Errorf("could not sign body: %q\n", err)
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
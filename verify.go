@@ -5,6 +5,7 @@ import (
 	"crypto"
 	"crypto/rsa"
 	"crypto/sha1"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
@@ -13,19 +14,81 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
-	"net"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
-// VerifyAlexaRequest authenticates whether the incoming request is from AWS
+// echoAPIHostname is the hostname the Alexa certificate chain must be
+// issued for, per the certificate chain verification steps.
+const echoAPIHostname = "echo-api.amazon.com"
+
+// defaultHTTPTimeout bounds how long a Verifier will wait on a cert chain
+// fetch before giving up.
+const defaultHTTPTimeout = 10 * time.Second
+
+// Verifier authenticates that an incoming HTTP request genuinely came from
+// the Alexa service, caching fetched certificate chains across requests.
+type Verifier struct {
+	// HTTPClient is used to fetch signature certificate chains. If nil, a
+	// client with defaultHTTPTimeout is used.
+	HTTPClient *http.Client
+
+	// CertCache stores parsed certificates keyed by SignatureCertChainUrl.
+	// If nil, an in-memory LRU cache is used.
+	CertCache CertCache
+
+	// CertCacheTTL bounds how long the default in-memory CertCache trusts a
+	// cached certificate even if its NotAfter is further out, so a
+	// revoked-but-not-yet-expired chain doesn't stick around forever. Only
+	// applies to the in-memory cache created when CertCache is nil; if
+	// zero, defaultCertCacheTTL is used.
+	CertCacheTTL time.Duration
+
+	// AllowedCertHost overrides the hostname SignatureCertChainUrl must
+	// match. Defaults to "s3.amazonaws.com". Tests override this to point
+	// at an httptest.Server serving a synthetic chain.
+	AllowedCertHost string
+
+	// AllowedCertPathPrefix overrides the URL path prefix
+	// SignatureCertChainUrl must match. Defaults to "/echo.api/".
+	AllowedCertPathPrefix string
+
+	// Roots, if set, overrides the certificate pool used to verify the
+	// fetched chain instead of trusting the chain PEM's own root
+	// certificate. Tests use this to validate a self-signed chain.
+	Roots *x509.CertPool
+
+	group         singleflight.Group
+	certCacheOnce sync.Once
+}
+
+// NewVerifier returns a Verifier with a default HTTP client and an
+// in-memory LRU CertCache.
+func NewVerifier() *Verifier {
+	return &Verifier{
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+		CertCache:  newLRUCertCache(defaultCertCacheSize, defaultCertCacheTTL),
+	}
+}
+
+// VerifyRequest authenticates whether the incoming request is from AWS. It
+// is a thin wrapper around a default Verifier's Middleware.
 func VerifyRequest(h http.Handler) http.HandlerFunc {
+	return NewVerifier().Middleware(h)
+}
+
+// Middleware wraps h, rejecting any request that does not pass Alexa's
+// request verification steps.
+func (v *Verifier) Middleware(h http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		sigCertChainURL := r.Header.Get("SignatureCertChainUrl")
 		// Check for valid sig chain url
-		if err := verifyCertURL(sigCertChainURL); err != nil {
+		if err := v.VerifyCertURL(sigCertChainURL); err != nil {
 			log.Printf("error: invalid SignatureCertChainURL: %q\n", err)
 			http.Error(w, "", http.StatusBadRequest)
 			return
@@ -46,16 +109,22 @@ func VerifyRequest(h http.Handler) http.HandlerFunc {
 		}
 
 		// vaidate certchain
-		cert, err := validateCertChain(sigCertChainURL)
+		cert, err := v.ValidateCertChain(sigCertChainURL)
 		if err != nil {
 			log.Printf("error: invalid certificate chain: %q\n", err)
 			http.Error(w, "", http.StatusBadRequest)
 			return
 		}
 
-		// verify signature
-		signature := r.Header.Get("Signature")
-		err = verifySignature(signature, cert.PublicKey.(*rsa.PublicKey), bytes.NewBuffer(bodyBuf))
+		// verify signature; prefer SHA-256 when Amazon sends it, falling
+		// back to the legacy SHA-1 signature for older test traffic.
+		hash := crypto.SHA256
+		signature := r.Header.Get("Signature-256")
+		if signature == "" {
+			hash = crypto.SHA1
+			signature = r.Header.Get("Signature")
+		}
+		err = v.VerifySignature(signature, hash, cert.PublicKey.(*rsa.PublicKey), bytes.NewBuffer(bodyBuf))
 		if err != nil {
 			log.Printf("error: could not verify signature: %q\n", err)
 			http.Error(w, "", http.StatusBadRequest)
@@ -70,7 +139,43 @@ func VerifyRequest(h http.Handler) http.HandlerFunc {
 	}
 }
 
-func verifyCertURL(certURL string) error {
+func (v *Verifier) httpClient() *http.Client {
+	if v.HTTPClient != nil {
+		return v.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// certCache returns v.CertCache, lazily initializing it to an in-memory LRU
+// cache the first time it's needed and storing it back on v so it persists
+// across requests instead of being rebuilt (and discarded) on every call.
+func (v *Verifier) certCache() CertCache {
+	v.certCacheOnce.Do(func() {
+		if v.CertCache == nil {
+			v.CertCache = newLRUCertCache(defaultCertCacheSize, v.CertCacheTTL)
+		}
+	})
+	return v.CertCache
+}
+
+func (v *Verifier) allowedCertHost() string {
+	if v.AllowedCertHost != "" {
+		return v.AllowedCertHost
+	}
+	return "s3.amazonaws.com"
+}
+
+func (v *Verifier) allowedCertPathPrefix() string {
+	if v.AllowedCertPathPrefix != "" {
+		return v.AllowedCertPathPrefix
+	}
+	return "/echo.api/"
+}
+
+// VerifyCertURL checks that certURL is an https URL whose host and path
+// match the whitelist the Alexa verification steps require (overridable
+// via AllowedCertHost / AllowedCertPathPrefix for tests).
+func (v *Verifier) VerifyCertURL(certURL string) error {
 	parsed, err := url.Parse(certURL)
 	if err != nil {
 		return fmt.Errorf("could not parse SignatureCertChainUrl: %q\n", err)
@@ -80,17 +185,11 @@ func verifyCertURL(certURL string) error {
 		return fmt.Errorf("scheme is not https: %q\n", parsed.Scheme)
 	}
 
-	if host, port, err := net.SplitHostPort(parsed.Host); err == nil {
-		if port != "443" || host != "s3.amazonaws.com" {
-			return fmt.Errorf("invalid hostname or port")
-		}
-	}
-
-	if !strings.HasPrefix(strings.ToLower(parsed.Host), "s3.amazonaws.com") {
+	if !strings.EqualFold(parsed.Hostname(), v.allowedCertHost()) {
 		return fmt.Errorf("invalid hostname")
 	}
 
-	if !strings.HasPrefix(parsed.Path, "/echo.api/") {
+	if !strings.HasPrefix(parsed.Path, v.allowedCertPathPrefix()) {
 		return fmt.Errorf("invalid path")
 	}
 	return nil
@@ -120,8 +219,35 @@ func verifyBodyTimestamp(body io.Reader) error {
 	return nil
 }
 
-func validateCertChain(chainURL string) (*x509.Certificate, error) {
-	resp, err := http.Get(chainURL)
+// ValidateCertChain returns the leaf certificate for chainURL, serving it
+// from cache when possible and coalescing concurrent misses for the same
+// URL into a single fetch.
+func (v *Verifier) ValidateCertChain(chainURL string) (*x509.Certificate, error) {
+	cache := v.certCache()
+	if cert, ok := cache.Get(chainURL); ok {
+		return cert, nil
+	}
+
+	result, err, _ := v.group.Do(chainURL, func() (interface{}, error) {
+		if cert, ok := cache.Get(chainURL); ok {
+			return cert, nil
+		}
+		cert, err := v.fetchCertChain(chainURL)
+		if err != nil {
+			return nil, err
+		}
+		expiresAt := cert.NotAfter
+		cache.Put(chainURL, cert, expiresAt)
+		return cert, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*x509.Certificate), nil
+}
+
+func (v *Verifier) fetchCertChain(chainURL string) (*x509.Certificate, error) {
+	resp, err := v.httpClient().Get(chainURL)
 	if err != nil {
 		return nil, fmt.Errorf("could not get cert chain pem: %q\n", err)
 	}
@@ -142,13 +268,34 @@ func validateCertChain(chainURL string) (*x509.Certificate, error) {
 		return nil, fmt.Errorf("could not parse cert chain: %q\n", err)
 	}
 
-	roots := x509.NewCertPool()
-	if ok := roots.AppendCertsFromPEM(buf); !ok {
-		return nil, fmt.Errorf("could not parse root cert: %q\n", err)
+	// cert.Verify below checks expiry against its own clock, but the Alexa
+	// verification steps call for an explicit, independent check.
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, fmt.Errorf("certificate is expired or not yet valid")
+	}
+
+	sanOK := false
+	for _, name := range cert.DNSNames {
+		if name == echoAPIHostname {
+			sanOK = true
+			break
+		}
+	}
+	if !sanOK {
+		return nil, fmt.Errorf("certificate SAN does not include %q", echoAPIHostname)
+	}
+
+	roots := v.Roots
+	if roots == nil {
+		roots = x509.NewCertPool()
+		if ok := roots.AppendCertsFromPEM(buf); !ok {
+			return nil, fmt.Errorf("could not parse root cert: %q\n", err)
+		}
 	}
 
 	opts := x509.VerifyOptions{
-		DNSName: "echo-api.amazon.com",
+		DNSName: echoAPIHostname,
 		Roots:   roots,
 	}
 
@@ -158,7 +305,9 @@ func validateCertChain(chainURL string) (*x509.Certificate, error) {
 	return cert, nil
 }
 
-func verifySignature(signature string, pubKey *rsa.PublicKey, body io.Reader) error {
+// VerifySignature checks that signature, base64-encoded, is a valid
+// PKCS#1 v1.5 signature of body's hash under pubKey.
+func (v *Verifier) VerifySignature(signature string, hash crypto.Hash, pubKey *rsa.PublicKey, body io.Reader) error {
 	data, err := base64.StdEncoding.DecodeString(signature)
 	if err != nil {
 		return fmt.Errorf("could not base64 decode signature: %q\n", err)
@@ -167,8 +316,18 @@ func verifySignature(signature string, pubKey *rsa.PublicKey, body io.Reader) er
 	if err != nil {
 		return fmt.Errorf("could not read request body: %q\n", err)
 	}
-	hashed := sha1.Sum(buf)
-	if err := rsa.VerifyPKCS1v15(pubKey, crypto.SHA1, hashed[:], data); err != nil {
+
+	var hashed []byte
+	switch hash {
+	case crypto.SHA256:
+		sum := sha256.Sum256(buf)
+		hashed = sum[:]
+	default:
+		sum := sha1.Sum(buf)
+		hashed = sum[:]
+	}
+
+	if err := rsa.VerifyPKCS1v15(pubKey, hash, hashed, data); err != nil {
 		return fmt.Errorf("verification error: %q\n", err)
 	}
 	return nil
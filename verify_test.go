@@ -0,0 +1,131 @@
+package glexa
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/davinche/glexa/testsign"
+)
+
+func newSignedBody(t *testing.T) []byte {
+	t.Helper()
+	ts := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	return []byte(fmt.Sprintf(`{
+		"version": "1.0",
+		"session": {"new": true, "sessionId": "session-1", "application": {"applicationId": "app-1"}},
+		"request": {"type": "LaunchRequest", "requestId": "req-1", "timestamp": %q}
+	}`, ts))
+}
+
+func newTestVerifier(t *testing.T, signer *testsign.Signer) *Verifier {
+	t.Helper()
+	return &Verifier{
+		HTTPClient:      signer.Client(),
+		AllowedCertHost: signer.Host(),
+		Roots:           signer.RootPool(),
+	}
+}
+
+func TestVerifierMiddlewareSHA256(t *testing.T) {
+	signer, err := testsign.New()
+	if err != nil {
+		t.Fatalf("testsign.New: %v", err)
+	}
+	defer signer.Close()
+
+	body := newSignedBody(t)
+	sig, err := signer.SignSHA256(body)
+	if err != nil {
+		t.Fatalf("SignSHA256: %v", err)
+	}
+
+	v := newTestVerifier(t, signer)
+	called := false
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("SignatureCertChainUrl", signer.ChainURL())
+	req.Header.Set("Signature-256", sig)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !called {
+		t.Fatal("expected wrapped handler to run")
+	}
+}
+
+func TestVerifierMiddlewareSHA1(t *testing.T) {
+	signer, err := testsign.New()
+	if err != nil {
+		t.Fatalf("testsign.New: %v", err)
+	}
+	defer signer.Close()
+
+	body := newSignedBody(t)
+	sig, err := signer.SignSHA1(body)
+	if err != nil {
+		t.Fatalf("SignSHA1: %v", err)
+	}
+
+	v := newTestVerifier(t, signer)
+	called := false
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("SignatureCertChainUrl", signer.ChainURL())
+	req.Header.Set("Signature", sig)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !called {
+		t.Fatal("expected wrapped handler to run")
+	}
+}
+
+func TestVerifierMiddlewareRejectsTamperedBody(t *testing.T) {
+	signer, err := testsign.New()
+	if err != nil {
+		t.Fatalf("testsign.New: %v", err)
+	}
+	defer signer.Close()
+
+	body := newSignedBody(t)
+	sig, err := signer.SignSHA256(body)
+	if err != nil {
+		t.Fatalf("SignSHA256: %v", err)
+	}
+
+	v := newTestVerifier(t, signer)
+	called := false
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	tampered := bytes.Replace(body, []byte("LaunchRequest"), []byte("IntentRequest...."), 1)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tampered))
+	req.Header.Set("SignatureCertChainUrl", signer.ChainURL())
+	req.Header.Set("Signature-256", sig)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for tampered body, got %d", w.Code)
+	}
+	if called {
+		t.Fatal("expected wrapped handler not to run for a tampered body")
+	}
+}